@@ -0,0 +1,239 @@
+package main
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// remoteAssetHosts are the CDNs Blogger serves post media from. Anything
+// else is assumed to already be under the user's control and is left alone.
+var remoteAssetHosts = []string{
+	"blogger.googleusercontent.com",
+	"bp.blogspot.com",
+	"lh3.googleusercontent.com",
+}
+
+// assetResult describes the outcome of fetching a single asset.
+type assetResult struct {
+	url    string
+	status string // "fetched", "skipped", or "failed"
+	err    error
+}
+
+// downloadAssets walks the HTML in content, downloads every remote image,
+// video link or <source> it finds into assetsDir (relative to dir) using a
+// bounded worker pool, and returns content with those URLs rewritten to
+// hrefDir, the path at which the caller's renderer will actually serve
+// them. hrefDir is usually the same as assetsDir, except for Hugo's flat
+// (non-page-bundle) layout, where assets live under a static/ tree that
+// Hugo serves with the static/ prefix stripped.
+func downloadAssets(content, dir, assetsDir, hrefDir string, timeout time.Duration) (string, []assetResult, error) {
+	doc, err := html.ParseFragment(strings.NewReader(content), &html.Node{
+		Type:     html.ElementNode,
+		Data:     "body",
+		DataAtom: atom.Body,
+	})
+	if err != nil {
+		return "", nil, fmt.Errorf("parsing entry content: %s", err)
+	}
+
+	type job struct {
+		node *html.Node
+		attr string
+		url  string
+	}
+
+	var jobs []job
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode {
+			switch n.DataAtom {
+			case atom.Img, atom.Source:
+				if u := attr(n, "src"); isRemoteAsset(u) {
+					jobs = append(jobs, job{n, "src", u})
+				}
+			case atom.A:
+				if u := attr(n, "href"); isRemoteAsset(u) && isMediaURL(u) {
+					jobs = append(jobs, job{n, "href", u})
+				}
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	for _, n := range doc {
+		walk(n)
+	}
+
+	if err := os.MkdirAll(filepath.Join(dir, assetsDir), DirectoryFilemode); err != nil {
+		return "", nil, err
+	}
+
+	const workers = 8
+	jobCh := make(chan job)
+	resultCh := make(chan assetResult, len(jobs))
+	var wg sync.WaitGroup
+	client := &http.Client{Timeout: timeout}
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobCh {
+				href, status, err := fetchAsset(client, j.url, dir, assetsDir, hrefDir)
+				if err == nil {
+					j.node.Attr = setAttr(j.node.Attr, j.attr, href)
+				}
+				resultCh <- assetResult{url: j.url, status: status, err: err}
+			}
+		}()
+	}
+
+	go func() {
+		for _, j := range jobs {
+			jobCh <- j
+		}
+		close(jobCh)
+	}()
+
+	wg.Wait()
+	close(resultCh)
+
+	var results []assetResult
+	for r := range resultCh {
+		results = append(results, r)
+	}
+
+	var buf strings.Builder
+	for _, n := range doc {
+		html.Render(&buf, n)
+	}
+
+	return buf.String(), results, nil
+}
+
+// fetchAsset downloads url into dir/assetsDir, naming the file after a hash
+// of the URL so re-imports are idempotent, and returns the href to use in
+// place of the original URL, which is the same file under hrefDir instead
+// of assetsDir.
+func fetchAsset(client *http.Client, rawURL, dir, assetsDir, hrefDir string) (href, status string, err error) {
+	sum := sha1.Sum([]byte(rawURL))
+	name := hex.EncodeToString(sum[:]) + assetExt(rawURL)
+	diskPath := filepath.Join(dir, assetsDir, name)
+	href = path.Join(hrefDir, name)
+
+	if _, err := os.Stat(diskPath); err == nil {
+		return href, "skipped", nil
+	}
+
+	resp, err := client.Get(rawURL)
+	if err != nil {
+		return "", "failed", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", "failed", fmt.Errorf("%s: %s", rawURL, resp.Status)
+	}
+
+	f, err := os.OpenFile(diskPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, FileFilemode)
+	if err != nil {
+		return "", "failed", err
+	}
+	defer f.Close()
+
+	if _, err := io.Copy(f, resp.Body); err != nil {
+		return "", "failed", err
+	}
+
+	return href, "fetched", nil
+}
+
+// hugoAssetHref converts assetsDir, a downloaded-asset directory relative
+// to the target dir (e.g. "static/blog/my-post"), into the site-rooted
+// path Hugo actually serves it at: everything under static/ is served
+// from the site root with the static/ prefix stripped.
+func hugoAssetHref(assetsDir string) string {
+	return "/" + strings.TrimPrefix(filepath.ToSlash(assetsDir), "static/")
+}
+
+func assetExt(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	if ext := path.Ext(u.Path); ext != "" {
+		return ext
+	}
+	return ".bin"
+}
+
+func isRemoteAsset(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return false
+	}
+	for _, host := range remoteAssetHosts {
+		if u.Host == host || strings.HasSuffix(u.Host, "."+host) {
+			return true
+		}
+	}
+	return false
+}
+
+var mediaExtensions = []string{".jpg", ".jpeg", ".png", ".gif", ".webp", ".mp4", ".webm", ".mov"}
+
+func isMediaURL(rawURL string) bool {
+	ext := strings.ToLower(path.Ext(rawURL))
+	for _, e := range mediaExtensions {
+		if ext == e {
+			return true
+		}
+	}
+	return false
+}
+
+func setAttr(attrs []html.Attribute, key, val string) []html.Attribute {
+	for i, a := range attrs {
+		if a.Key == key {
+			attrs[i].Val = val
+			return attrs
+		}
+	}
+	return append(attrs, html.Attribute{Key: key, Val: val})
+}
+
+// logAssetSummary prints a one-line tally of fetched/skipped/failed assets
+// for a single post, and logs each failure so it can be retried by hand.
+func logAssetSummary(title string, results []assetResult) {
+	var fetched, skipped, failed int
+	for _, r := range results {
+		switch r.status {
+		case "fetched":
+			fetched++
+		case "skipped":
+			skipped++
+		case "failed":
+			failed++
+			log.Printf("asset failed for %q: %s: %s", title, r.url, r.err)
+		}
+	}
+	if len(results) > 0 {
+		log.Printf("%q: %d assets fetched, %d skipped, %d failed", title, fetched, skipped, failed)
+	}
+}
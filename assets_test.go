@@ -0,0 +1,211 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestIsRemoteAsset(t *testing.T) {
+	tests := []struct {
+		url  string
+		want bool
+	}{
+		{"https://blogger.googleusercontent.com/img/a.png", true},
+		{"https://sub.bp.blogspot.com/img/a.png", true},
+		{"https://lh3.googleusercontent.com/a.png", true},
+		{"https://example.com/img/a.png", false},
+		{"not a url", false},
+		{"/relative/path.png", false},
+	}
+
+	for _, tt := range tests {
+		if got := isRemoteAsset(tt.url); got != tt.want {
+			t.Errorf("isRemoteAsset(%q) = %v, want %v", tt.url, got, tt.want)
+		}
+	}
+}
+
+func TestIsMediaURL(t *testing.T) {
+	tests := []struct {
+		url  string
+		want bool
+	}{
+		{"https://example.com/a.jpg", true},
+		{"https://example.com/a.PNG", true},
+		{"https://example.com/a.webm", true},
+		{"https://example.com/a.html", false},
+		{"https://example.com/a", false},
+	}
+
+	for _, tt := range tests {
+		if got := isMediaURL(tt.url); got != tt.want {
+			t.Errorf("isMediaURL(%q) = %v, want %v", tt.url, got, tt.want)
+		}
+	}
+}
+
+func TestAssetExt(t *testing.T) {
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{"https://example.com/a.png", ".png"},
+		{"https://example.com/a.jpeg?w=200", ".jpeg"},
+		{"https://example.com/a", ".bin"},
+		{"not a url%", ""},
+	}
+
+	for _, tt := range tests {
+		if got := assetExt(tt.url); got != tt.want {
+			t.Errorf("assetExt(%q) = %q, want %q", tt.url, got, tt.want)
+		}
+	}
+}
+
+func TestHugoAssetHref(t *testing.T) {
+	tests := []struct {
+		assetsDir string
+		want      string
+	}{
+		{"static/blog/my-post", "/blog/my-post"},
+		{"static", "/static"},
+		{"assets/my-post", "/assets/my-post"},
+	}
+
+	for _, tt := range tests {
+		if got := hugoAssetHref(tt.assetsDir); got != tt.want {
+			t.Errorf("hugoAssetHref(%q) = %q, want %q", tt.assetsDir, got, tt.want)
+		}
+	}
+}
+
+func TestDownloadAssetsHrefDirDiffersFromDiskDir(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("fake-png-bytes"))
+	}))
+	defer srv.Close()
+
+	orig := remoteAssetHosts
+	host := strings.TrimPrefix(strings.TrimPrefix(srv.URL, "http://"), "https://")
+	remoteAssetHosts = append(orig, host)
+	defer func() { remoteAssetHosts = orig }()
+
+	content := fmt.Sprintf(`<p><img src="%s/ok.png"></p>`, srv.URL)
+	dir := t.TempDir()
+
+	rewritten, _, err := downloadAssets(content, dir, "static/blog/my-post", "/blog/my-post", time.Second)
+	if err != nil {
+		t.Fatalf("downloadAssets: %s", err)
+	}
+
+	if strings.Contains(rewritten, "static/blog/my-post") {
+		t.Errorf("rewritten href should not contain the static/ disk path: %s", rewritten)
+	}
+	if !strings.Contains(rewritten, `src="/blog/my-post/`) {
+		t.Errorf("rewritten href should be site-rooted under /blog/my-post: %s", rewritten)
+	}
+
+	entries, err := os.ReadDir(filepath.Join(dir, "static/blog/my-post"))
+	if err != nil {
+		t.Fatalf("reading assets dir: %s", err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("expected 1 downloaded asset on disk under static/blog/my-post, got %d", len(entries))
+	}
+}
+
+func TestDownloadAssets(t *testing.T) {
+	var hits int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/ok.png":
+			hits++
+			w.Write([]byte("fake-png-bytes"))
+		case "/missing.png":
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	content := fmt.Sprintf(
+		`<p><img src="%s/ok.png"><img src="%s/missing.png"><img src="https://example.com/other.png"></p>`,
+		srv.URL, srv.URL,
+	)
+	// isRemoteAsset only recognizes Blogger's CDN hosts, so point the test
+	// server's URLs through one of them via a host rewrite isn't possible;
+	// instead exercise fetchAsset/downloadAssets against remoteAssetHosts
+	// directly by temporarily widening the host allowlist.
+	orig := remoteAssetHosts
+	host := strings.TrimPrefix(strings.TrimPrefix(srv.URL, "http://"), "https://")
+	remoteAssetHosts = append(orig, host)
+	defer func() { remoteAssetHosts = orig }()
+
+	dir := t.TempDir()
+
+	rewritten, results, err := downloadAssets(content, dir, "assets", "assets", time.Second)
+	if err != nil {
+		t.Fatalf("downloadAssets: %s", err)
+	}
+
+	var fetched, failed int
+	for _, r := range results {
+		switch r.status {
+		case "fetched":
+			fetched++
+		case "failed":
+			failed++
+		}
+	}
+	if fetched != 1 || failed != 1 {
+		t.Fatalf("got %d fetched, %d failed results (%+v), want 1 fetched, 1 failed", fetched, failed, results)
+	}
+	if hits != 1 {
+		t.Fatalf("server got %d hits, want 1", hits)
+	}
+
+	if strings.Contains(rewritten, srv.URL+"/ok.png") {
+		t.Errorf("rewritten content still references the fetched asset's remote URL: %s", rewritten)
+	}
+	if !strings.Contains(rewritten, srv.URL+"/missing.png") {
+		t.Errorf("failed fetch should leave its URL unrewritten: %s", rewritten)
+	}
+	if !strings.Contains(rewritten, "https://example.com/other.png") {
+		t.Errorf("non-remote-asset URL should be left untouched: %s", rewritten)
+	}
+	if !strings.Contains(rewritten, filepath.ToSlash(filepath.Join("assets"))) {
+		t.Errorf("rewritten content should reference the local assets dir: %s", rewritten)
+	}
+
+	entries, err := os.ReadDir(filepath.Join(dir, "assets"))
+	if err != nil {
+		t.Fatalf("reading assets dir: %s", err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("expected 1 downloaded asset on disk, got %d", len(entries))
+	}
+
+	// Re-running against the same dir should skip the already-downloaded
+	// asset instead of re-fetching it.
+	_, results, err = downloadAssets(content, dir, "assets", "assets", time.Second)
+	if err != nil {
+		t.Fatalf("downloadAssets (second run): %s", err)
+	}
+	var skipped int
+	for _, r := range results {
+		if r.status == "skipped" {
+			skipped++
+		}
+	}
+	if skipped != 1 {
+		t.Fatalf("got %d skipped results, want 1", skipped)
+	}
+	if hits != 1 {
+		t.Fatalf("server got %d hits after re-run, want still 1 (idempotent skip)", hits)
+	}
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"log"
+	"sort"
+	"strings"
+	"time"
+)
+
+// commentsMode controls how writeEntry attaches a post's comments, if any.
+type commentsMode string
+
+const (
+	commentsSkip    commentsMode = "skip"
+	commentsInline  commentsMode = "inline"
+	commentsSidecar commentsMode = "sidecar"
+)
+
+const kindComment = "http://schemas.google.com/blogger/2008/kind#comment"
+
+// Comment is a single Blogger comment, threaded under the post (or parent
+// comment) it replies to.
+type Comment struct {
+	ID        string
+	Published Date
+	Author    Author
+	Content   string
+	Replies   []*Comment
+}
+
+func isComment(e Entry) bool {
+	for _, tag := range e.Tags {
+		if tag.Name == kindComment && tag.Scheme == "http://schemas.google.com/g/2005#kind" {
+			return true
+		}
+	}
+	return false
+}
+
+// parentPostID returns the ID of the post a comment ID belongs to. Blogger
+// comment IDs nest the post's own ID as a prefix, e.g.
+// "tag:blogger.com,1999:blog-123.post-456.comment-789" belongs to post
+// "tag:blogger.com,1999:blog-123.post-456".
+func parentPostID(commentID string) string {
+	if i := strings.Index(commentID, ".comment-"); i >= 0 {
+		return commentID[:i]
+	}
+	return ""
+}
+
+// groupComments threads every comment entry in entries under the post it
+// belongs to, resolving thr:in-reply-to links into a Replies tree. Comments
+// whose in-reply-to target isn't found become top-level comments of their
+// post, with a warning logged so the gap is noticeable.
+func groupComments(entries []Entry) map[string][]*Comment {
+	byID := map[string]*Comment{}
+	postOf := map[string]string{}
+
+	for _, e := range entries {
+		if !isComment(e) {
+			continue
+		}
+		c := &Comment{
+			ID:        e.ID,
+			Published: e.Published,
+			Author:    e.Author,
+			Content:   e.Content,
+		}
+		byID[c.ID] = c
+		postOf[c.ID] = parentPostID(e.ID)
+	}
+
+	topLevel := map[string][]*Comment{}
+	for _, e := range entries {
+		if !isComment(e) {
+			continue
+		}
+		c := byID[e.ID]
+		post := postOf[e.ID]
+
+		replyTo := ""
+		if e.InReplyTo != nil {
+			replyTo = e.InReplyTo.Ref
+		}
+
+		switch {
+		case replyTo == "" || replyTo == post:
+			topLevel[post] = append(topLevel[post], c)
+		default:
+			if parent, ok := byID[replyTo]; ok {
+				parent.Replies = append(parent.Replies, c)
+			} else {
+				log.Printf("comment %q replies to %q, which wasn't found; treating as top-level", c.ID, replyTo)
+				topLevel[post] = append(topLevel[post], c)
+			}
+		}
+	}
+
+	for _, comments := range topLevel {
+		sortComments(comments)
+	}
+	for _, c := range byID {
+		sortComments(c.Replies)
+	}
+
+	return topLevel
+}
+
+func sortComments(comments []*Comment) {
+	sort.Slice(comments, func(i, j int) bool {
+		return time.Time(comments[i].Published).Before(time.Time(comments[j].Published))
+	})
+}
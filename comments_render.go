@@ -0,0 +1,75 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// commentsTOML renders comments as nested [[comments]] arrays of tables,
+// suitable either for splicing into a post's front matter (inline mode) or
+// for a standalone .comments.toml sidecar.
+func commentsTOML(comments []*Comment) string {
+	var buf strings.Builder
+	writeCommentsTOML(&buf, "comments", comments)
+	return buf.String()
+}
+
+func writeCommentsTOML(buf *strings.Builder, table string, comments []*Comment) {
+	for _, c := range comments {
+		fmt.Fprintf(buf, "[[%s]]\n", table)
+		fmt.Fprintf(buf, "  author = %s\n", QuoteStringValue(c.Author.Name))
+		fmt.Fprintf(buf, "  published = %s\n", c.Published)
+		fmt.Fprintf(buf, "  content = %s\n", QuoteStringValue(c.Content))
+		if len(c.Replies) > 0 {
+			writeCommentsTOML(buf, table+".replies", c.Replies)
+		}
+	}
+}
+
+// commentsYAML renders comments as a nested YAML sequence, for splicing
+// into posts whose front matter is YAML rather than TOML (-format=md).
+func commentsYAML(comments []*Comment) string {
+	var buf strings.Builder
+	buf.WriteString("comments:\n")
+	writeCommentsYAML(&buf, "  ", comments)
+	return buf.String()
+}
+
+func writeCommentsYAML(buf *strings.Builder, indent string, comments []*Comment) {
+	for _, c := range comments {
+		fmt.Fprintf(buf, "%s- author: %s\n", indent, QuoteStringValue(c.Author.Name))
+		fmt.Fprintf(buf, "%s  published: %s\n", indent, c.Published)
+		fmt.Fprintf(buf, "%s  content: %s\n", indent, QuoteStringValue(c.Content))
+		if len(c.Replies) > 0 {
+			fmt.Fprintf(buf, "%s  replies:\n", indent)
+			writeCommentsYAML(buf, indent+"    ", c.Replies)
+		}
+	}
+}
+
+// writeCommentsSidecar writes comments to filename as either JSON or TOML,
+// per sidecarFormat ("json" or "toml").
+func writeCommentsSidecar(comments []*Comment, filename, sidecarFormat string) error {
+	var out []byte
+	switch sidecarFormat {
+	case "toml":
+		out = []byte(commentsTOML(comments))
+	default:
+		b, err := json.MarshalIndent(comments, "", "  ")
+		if err != nil {
+			return err
+		}
+		out = b
+	}
+
+	f, err := os.OpenFile(filename, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, FileFilemode)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = f.Write(out)
+	return err
+}
@@ -0,0 +1,47 @@
+package main
+
+import "testing"
+
+func commentEntry(id, postID, inReplyTo string) Entry {
+	e := Entry{
+		ID: id,
+		Tags: Tags{
+			{Name: kindComment, Scheme: "http://schemas.google.com/g/2005#kind"},
+		},
+	}
+	if inReplyTo != "" {
+		e.InReplyTo = &InReplyTo{Ref: inReplyTo}
+	}
+	_ = postID
+	return e
+}
+
+func TestGroupCommentsThreading(t *testing.T) {
+	const post = "tag:blogger.com,1999:blog-1.post-1"
+
+	entries := []Entry{
+		commentEntry(post+".comment-1", post, post),
+		commentEntry(post+".comment-2", post, post+".comment-1"),
+		commentEntry(post+".comment-3", post, "tag:blogger.com,1999:blog-1.post-1.comment-missing"),
+	}
+
+	grouped := groupComments(entries)
+	top := grouped[post]
+
+	if len(top) != 2 {
+		t.Fatalf("expected 2 top-level comments (1 direct reply + 1 orphan), got %d", len(top))
+	}
+
+	var root *Comment
+	for _, c := range top {
+		if c.ID == post+".comment-1" {
+			root = c
+		}
+	}
+	if root == nil {
+		t.Fatalf("comment-1 not found at top level")
+	}
+	if len(root.Replies) != 1 || root.Replies[0].ID != post+".comment-2" {
+		t.Errorf("expected comment-2 threaded under comment-1, got %+v", root.Replies)
+	}
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"encoding/json"
 	"encoding/xml"
 	"flag"
 	"fmt"
@@ -20,6 +21,10 @@ func (d Date) String() string {
 	return time.Time(d).Format("2006-01-02T15:04:05Z")
 }
 
+func (d Date) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Time(d).Format(time.RFC3339))
+}
+
 // Returns a string surrounded by quotes ("), its quotes escaped as \".
 func QuoteStringValue(str string) string {
 	return fmt.Sprintf("%q", str)
@@ -63,16 +68,43 @@ type Export struct {
 }
 
 type Entry struct {
-	ID        string `xml:"id"`
-	Published Date   `xml:"published"`
-	Updated   Date   `xml:"updated"`
-	Draft     Draft  `xml:"control>draft"`
-	Title     string `xml:"title"`
-	Content   string `xml:"content"`
-	Tags      Tags   `xml:"category"`
-	Author    Author `xml:"author"`
+	ID        string     `xml:"id"`
+	Published Date       `xml:"published"`
+	Updated   Date       `xml:"updated"`
+	Draft     Draft      `xml:"control>draft"`
+	Title     string     `xml:"title"`
+	Content   string     `xml:"content"`
+	Tags      Tags       `xml:"category"`
+	Author    Author     `xml:"author"`
+	InReplyTo *InReplyTo `xml:"in-reply-to"`
+	Links     []Link     `xml:"link"`
 	Extra     string
 }
+
+// InReplyTo is thr:in-reply-to. Comment entries carry one, with Ref set to
+// the ID of whatever they're replying to: the post for a top-level
+// comment, or another comment for a threaded reply.
+type InReplyTo struct {
+	Ref string `xml:"ref,attr"`
+}
+
+// Link is an Atom <link>. Blogger includes one with rel="alternate"
+// pointing at the post's original permalink.
+type Link struct {
+	Rel  string `xml:"rel,attr"`
+	Href string `xml:"href,attr"`
+}
+
+// Permalink returns the entry's original Blogger URL, or "" if it has none.
+func (e Entry) Permalink() string {
+	for _, l := range e.Links {
+		if l.Rel == "alternate" {
+			return l.Href
+		}
+	}
+	return ""
+}
+
 type Tag struct {
 	Name   string `xml:"term,attr"`
 	Scheme string `xml:"scheme,attr"`
@@ -106,10 +138,57 @@ blogimport = true {{ with .Extra }}
 {{ .Content }}
 `
 
+// mdTempl is used when -format=md. Hugo reads YAML front matter fine, and
+// it's the register the rest of the Hugo ecosystem expects around Markdown
+// content, so we switch delimiters even though the TOML body is unchanged.
+var mdTempl = `---
+title: {{ QuoteStringValue .Title }}
+date: {{ .Published }}
+updated: {{ .Updated }}{{ with .Tags.TomlString }}
+tags: [{{ . }}]{{ end }}{{ if .Draft }}
+draft: true{{ end }}
+blogimport: true {{ with .Extra }}
+{{.}}{{ end }}
+author:
+  name: {{ QuoteStringValue .Author.Name }}
+  uri: {{ QuoteStringValue .Author.Uri }}
+---
+
+{{ .Content }}
+`
+
 var t = template.Must(template.New("").Funcs(template.FuncMap{
 	"QuoteStringValue": QuoteStringValue,
 }).Parse(templ))
 
+var mdT = template.Must(template.New("").Funcs(template.FuncMap{
+	"QuoteStringValue": QuoteStringValue,
+}).Parse(mdTempl))
+
+// format controls how writeEntry renders an entry to disk.
+type format string
+
+const (
+	formatHTML format = "html"
+	formatMD   format = "md"
+)
+
+// writeOptions bundles the per-run settings writeEntry needs, beyond the
+// entry and target directory, so that adding a new flag doesn't mean
+// growing writeEntry's parameter list again.
+type writeOptions struct {
+	format          format
+	target          target
+	downloadAssets  bool
+	assetsDir       string
+	timeout         time.Duration
+	comments        commentsMode
+	commentsFormat  string
+	templatePath    string
+	slug            slugStrategy
+	filenameFromURL bool
+}
+
 // Owner: read, write & execute. Other: Read & execute.
 // See: https://stackoverflow.com/questions/18415904/what-does-mode-t-0644-mean
 const DirectoryFilemode = 0755
@@ -122,8 +201,65 @@ func main() {
 	log.SetFlags(0)
 
 	extra := flag.String("extra", "", "additional metadata to set in frontmatter")
+	formatFlag := flag.String("format", string(formatHTML), "output format for post content: html or md")
+	targetFlag := flag.String("target", string(targetHugo), "static site generator to render front matter for: hugo or zola")
+	downloadAssetsFlag := flag.Bool("download-assets", false, "download images/video referenced in posts and rewrite links to point at the local copy")
+	assetsDir := flag.String("assets-dir", "static/blog", "directory (relative to <targetdir>) that downloaded assets are stored under, per post")
+	timeout := flag.Duration("timeout", 30*time.Second, "timeout for each asset download, when -download-assets is set")
+	commentsFlag := flag.String("comments", string(commentsSkip), "how to attach a post's comments: skip, inline, or sidecar")
+	commentsFormatFlag := flag.String("comments-format", "json", "sidecar file format when -comments=sidecar: json or toml")
+	templateFlag := flag.String("template", "", "path to an alternate text/template to render posts with, in place of the built-in hugo/zola templates")
+	slugFlag := flag.String("slug", string(slugUnicode), "slug strategy for post filenames: unicode, ascii, or date-title")
+	filenameFromURLFlag := flag.Bool("filename-from-url", false, "derive each post's slug from its original Blogger permalink instead of its title")
 	flag.Parse()
 
+	f := format(*formatFlag)
+	switch f {
+	case formatHTML, formatMD:
+	default:
+		log.Fatalf("Unknown -format %q, must be %q or %q", *formatFlag, formatHTML, formatMD)
+	}
+
+	tg := target(*targetFlag)
+	switch tg {
+	case targetHugo, targetZola:
+	default:
+		log.Fatalf("Unknown -target %q, must be %q or %q", *targetFlag, targetHugo, targetZola)
+	}
+
+	cm := commentsMode(*commentsFlag)
+	switch cm {
+	case commentsSkip, commentsInline, commentsSidecar:
+	default:
+		log.Fatalf("Unknown -comments %q, must be %q, %q or %q", *commentsFlag, commentsSkip, commentsInline, commentsSidecar)
+	}
+
+	sl := slugStrategy(*slugFlag)
+	switch sl {
+	case slugUnicode, slugASCII, slugDateTitle:
+	default:
+		log.Fatalf("Unknown -slug %q, must be %q, %q or %q", *slugFlag, slugUnicode, slugASCII, slugDateTitle)
+	}
+
+	switch *commentsFormatFlag {
+	case "json", "toml":
+	default:
+		log.Fatalf("Unknown -comments-format %q, must be %q or %q", *commentsFormatFlag, "json", "toml")
+	}
+
+	opts := writeOptions{
+		format:          f,
+		target:          tg,
+		downloadAssets:  *downloadAssetsFlag,
+		assetsDir:       *assetsDir,
+		timeout:         *timeout,
+		comments:        cm,
+		commentsFormat:  *commentsFormatFlag,
+		templatePath:    *templateFlag,
+		slug:            sl,
+		filenameFromURL: *filenameFromURLFlag,
+	}
+
 	args := flag.Args()
 
 	if len(args) != 2 {
@@ -165,6 +301,8 @@ func main() {
 		log.Fatal("No blog entries found!")
 	}
 
+	comments := groupComments(exp.Entries)
+
 	count := 0
 	drafts := 0
 	for _, entry := range exp.Entries {
@@ -182,7 +320,7 @@ func main() {
 		if extra != nil {
 			entry.Extra = *extra
 		}
-		if err := writeEntry(entry, dir); err != nil {
+		if err := writeEntry(entry, dir, opts, comments[entry.ID]); err != nil {
 			log.Fatalf("Failed writing post %q to disk:\n%s", entry.Title, err)
 		}
 		if entry.Draft {
@@ -195,25 +333,80 @@ func main() {
 	log.Printf("Wrote %d drafts to disk.", drafts)
 }
 
-func writeEntry(e Entry, dir string) error {
-	// Blogger posts are written in stored as HTML.
-	// Don't save this with a .md extension or hugo
-	// will insert <p> tags at the start of each post.
-	extension := ".html"
-	filename := filepath.Join(dir, makePath(e.Title)+extension)
-	f, err := os.OpenFile(filename, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, FileFilemode)
+func writeEntry(e Entry, dir string, opts writeOptions, comments []*Comment) error {
+	renderer, err := newRenderer(opts)
 	if err != nil {
 		return err
 	}
-	defer f.Close()
 
-	return t.Execute(f, e)
-}
+	slug := makeSlug(e, opts.slug, opts.filenameFromURL)
+	postDir := dir
+	assetsDir := filepath.Join(opts.assetsDir, slug)
+	hrefDir := assetsDir
+	filename := filepath.Join(dir, slug+renderer.Ext())
+
+	if renderer.PageBundle() {
+		// Page bundles keep a post's assets next to its index file
+		// rather than under a shared static/ tree.
+		postDir = filepath.Join(dir, slug)
+		assetsDir = ""
+		hrefDir = ""
+		filename = filepath.Join(postDir, "index"+renderer.Ext())
+	} else {
+		// Flat-file layouts (Hugo's default) store assets under a
+		// static/ tree that's served from the site root with the
+		// static/ prefix stripped, so the href differs from the path
+		// on disk.
+		hrefDir = hugoAssetHref(assetsDir)
+	}
+
+	// The slug may contain subdirectories (date-title, or a multi-segment
+	// permalink from -filename-from-url), so make sure they exist.
+	if err := os.MkdirAll(filepath.Dir(filename), DirectoryFilemode); err != nil {
+		return err
+	}
+
+	if opts.downloadAssets {
+		content, results, err := downloadAssets(e.Content, postDir, assetsDir, hrefDir, opts.timeout)
+		if err != nil {
+			return fmt.Errorf("downloading assets for %q: %s", e.Title, err)
+		}
+		logAssetSummary(e.Title, results)
+		e.Content = content
+	}
+
+	if len(comments) > 0 {
+		switch opts.comments {
+		case commentsInline:
+			block := renderer.CommentsBlock(comments)
+			if e.Extra != "" {
+				e.Extra += "\n"
+			}
+			e.Extra += block
+		case commentsSidecar:
+			sidecar := filepath.Join(postDir, slug+".comments."+opts.commentsFormat)
+			if renderer.PageBundle() {
+				sidecar = filepath.Join(postDir, "comments."+opts.commentsFormat)
+			}
+			if err := writeCommentsSidecar(comments, sidecar, opts.commentsFormat); err != nil {
+				return fmt.Errorf("writing comments sidecar for %q: %s", e.Title, err)
+			}
+		}
+	}
+
+	out, err := renderer.Render(e)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(filename, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, FileFilemode)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
 
-// Take a string with any characters and replace it so the string could be used in a path.
-// E.g. Social Media -> social-media
-func makePath(s string) string {
-	return unicodeSanitize(strings.ToLower(strings.Replace(strings.TrimSpace(s), " ", "-", -1)))
+	_, err = file.Write(out)
+	return err
 }
 
 func unicodeSanitize(s string) string {
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// htmlToMarkdown converts a fragment of HTML, as stored in a Blogger entry's
+// Content field, into CommonMark. Constructs it doesn't recognize are passed
+// through as raw inline HTML so that no content is lost.
+func htmlToMarkdown(content string) (string, error) {
+	nodes, err := html.ParseFragment(strings.NewReader(content), &html.Node{
+		Type:     html.ElementNode,
+		Data:     "body",
+		DataAtom: atom.Body,
+	})
+	if err != nil {
+		return "", fmt.Errorf("parsing entry content: %s", err)
+	}
+
+	var buf strings.Builder
+	for _, n := range nodes {
+		renderMarkdown(&buf, n, 0)
+	}
+	return strings.TrimSpace(buf.String()) + "\n", nil
+}
+
+// renderMarkdown walks n and its children, writing CommonMark to buf.
+// listDepth tracks nesting for indenting <li> content in <ul>/<ol>.
+func renderMarkdown(buf *strings.Builder, n *html.Node, listDepth int) {
+	switch n.Type {
+	case html.TextNode:
+		buf.WriteString(escapeMarkdown(n.Data))
+		return
+	case html.CommentNode:
+		return
+	}
+
+	if n.Type != html.ElementNode {
+		renderChildren(buf, n, listDepth)
+		return
+	}
+
+	switch n.DataAtom {
+	case atom.P:
+		renderChildren(buf, n, listDepth)
+		buf.WriteString("\n\n")
+	case atom.Br:
+		buf.WriteString("  \n")
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+		level, _ := strconv.Atoi(strings.TrimPrefix(n.Data, "h"))
+		buf.WriteString(strings.Repeat("#", level))
+		buf.WriteString(" ")
+		renderChildren(buf, n, listDepth)
+		buf.WriteString("\n\n")
+	case atom.A:
+		href := attr(n, "href")
+		buf.WriteString("[")
+		renderChildren(buf, n, listDepth)
+		buf.WriteString("](")
+		buf.WriteString(href)
+		buf.WriteString(")")
+	case atom.Img:
+		src := attr(n, "src")
+		alt := attr(n, "alt")
+		buf.WriteString("![")
+		buf.WriteString(alt)
+		buf.WriteString("](")
+		buf.WriteString(src)
+		buf.WriteString(")")
+	case atom.Strong, atom.B:
+		buf.WriteString("**")
+		renderChildren(buf, n, listDepth)
+		buf.WriteString("**")
+	case atom.Em, atom.I:
+		buf.WriteString("*")
+		renderChildren(buf, n, listDepth)
+		buf.WriteString("*")
+	case atom.Ul, atom.Ol:
+		if rendered := buf.String(); rendered != "" && !strings.HasSuffix(rendered, "\n") {
+			buf.WriteString("\n")
+		}
+		renderList(buf, n, listDepth)
+		if listDepth == 0 {
+			buf.WriteString("\n")
+		}
+	case atom.Blockquote:
+		var inner strings.Builder
+		renderChildren(&inner, n, listDepth)
+		for _, line := range strings.Split(strings.TrimRight(inner.String(), "\n"), "\n") {
+			buf.WriteString("> ")
+			buf.WriteString(line)
+			buf.WriteString("\n")
+		}
+		buf.WriteString("\n")
+	case atom.Pre:
+		buf.WriteString("```\n")
+		buf.WriteString(textContent(n))
+		buf.WriteString("\n```\n\n")
+	case atom.Code:
+		buf.WriteString("`")
+		buf.WriteString(textContent(n))
+		buf.WriteString("`")
+	case atom.Hr:
+		buf.WriteString("---\n\n")
+	default:
+		// No CommonMark equivalent: fall back to the raw tag so content
+		// survives the round trip.
+		html.Render(buf, n)
+	}
+}
+
+func renderList(buf *strings.Builder, n *html.Node, depth int) {
+	ordered := n.DataAtom == atom.Ol
+	i := 1
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type != html.ElementNode || c.DataAtom != atom.Li {
+			continue
+		}
+		buf.WriteString(strings.Repeat("  ", depth))
+		if ordered {
+			buf.WriteString(fmt.Sprintf("%d. ", i))
+		} else {
+			buf.WriteString("- ")
+		}
+		i++
+		var item strings.Builder
+		renderChildren(&item, c, depth+1)
+		buf.WriteString(strings.TrimSpace(item.String()))
+		buf.WriteString("\n")
+	}
+}
+
+func renderChildren(buf *strings.Builder, n *html.Node, listDepth int) {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		renderMarkdown(buf, c, listDepth)
+	}
+}
+
+// markdownEscaper backslash-escapes the CommonMark metacharacters so that
+// plain text copied out of HTML (e.g. "func(*x)") can't be misread as
+// emphasis, code spans, or links once it's inline Markdown. Backslash
+// itself comes first so an already-escaped character isn't double-escaped.
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	`*`, `\*`,
+	`_`, `\_`,
+	"`", "\\`",
+	`[`, `\[`,
+	`]`, `\]`,
+)
+
+func escapeMarkdown(s string) string {
+	return markdownEscaper.Replace(s)
+}
+
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+func textContent(n *html.Node) string {
+	var buf strings.Builder
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.TextNode {
+			buf.WriteString(n.Data)
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(n)
+	return strings.TrimRight(buf.String(), "\n")
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHtmlToMarkdown(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{
+			name: "paragraphs and formatting",
+			in:   "<p>Hello <strong>world</strong>, this is <em>great</em>.</p>",
+			want: "Hello **world**, this is *great*.",
+		},
+		{
+			name: "heading",
+			in:   "<h2>A Title</h2><p>Body text.</p>",
+			want: "## A Title\n\nBody text.",
+		},
+		{
+			name: "link and image",
+			in:   `<p>See <a href="https://example.com">this</a> and <img src="https://example.com/cat.png" alt="a cat"></p>`,
+			want: `See [this](https://example.com) and ![a cat](https://example.com/cat.png)`,
+		},
+		{
+			name: "nested list",
+			in:   "<ul><li>one</li><li>two<ul><li>nested</li></ul></li></ul>",
+			want: "- one\n- two\n  - nested",
+		},
+		{
+			name: "ordered list",
+			in:   "<ol><li>first</li><li>second</li></ol>",
+			want: "1. first\n2. second",
+		},
+		{
+			name: "blockquote",
+			in:   "<blockquote>Someone said this.</blockquote>",
+			want: "> Someone said this.",
+		},
+		{
+			name: "code block",
+			in:   "<pre>func main() {}</pre>",
+			want: "```\nfunc main() {}\n```",
+		},
+		{
+			name: "horizontal rule",
+			in:   "<p>Before</p><hr><p>After</p>",
+			want: "Before\n\n---\n\nAfter",
+		},
+		{
+			name: "unrecognized tag falls back to raw html",
+			in:   `<p>Check out this <video src="movie.mp4"></video></p>`,
+			want: `<video src="movie.mp4"></video>`,
+		},
+		{
+			name: "literal markdown metacharacters in plain text are escaped",
+			in:   `<p>The variable *foo* (not bold) holds a value, and func(*x) dereferences it.</p>`,
+			want: `The variable \*foo\* (not bold) holds a value, and func(\*x) dereferences it.`,
+		},
+		{
+			name: "inline code content is left verbatim",
+			in:   `<p>Use <code>foo_bar(*x)</code> here.</p>`,
+			want: "Use `foo_bar(*x)` here.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := htmlToMarkdown(tt.in)
+			if err != nil {
+				t.Fatalf("htmlToMarkdown: %s", err)
+			}
+			if !strings.Contains(got, tt.want) {
+				t.Errorf("htmlToMarkdown(%q) = %q, want it to contain %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
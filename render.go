@@ -0,0 +1,145 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"text/template"
+)
+
+// target selects which static site generator's front matter and layout
+// writeEntry produces.
+type target string
+
+const (
+	targetHugo target = "hugo"
+	targetZola target = "zola"
+)
+
+// Renderer turns an Entry into the bytes of a post file for a particular
+// static site generator, and says how that file should be laid out on
+// disk.
+type Renderer interface {
+	// Render returns the full contents (front matter plus body) to write
+	// for e.
+	Render(e Entry) ([]byte, error)
+	// Ext is the file extension Render's output should be saved with.
+	Ext() string
+	// PageBundle reports whether posts are laid out as
+	// <slug>/index.<ext> directories rather than <slug>.<ext> files.
+	// Page bundles let downloaded assets live next to the post they
+	// belong to.
+	PageBundle() bool
+	// CommentsBlock renders comments in whatever front matter dialect
+	// (TOML or YAML) this renderer's front matter uses, for splicing in
+	// under -comments=inline.
+	CommentsBlock(comments []*Comment) string
+}
+
+// HugoRenderer renders the TOML/YAML front matter this tool has always
+// produced for Hugo, in either HTML or Markdown content format.
+type HugoRenderer struct {
+	Format format
+}
+
+func (h HugoRenderer) Render(e Entry) ([]byte, error) {
+	tmpl := t
+	if h.Format == formatMD {
+		md, err := htmlToMarkdown(e.Content)
+		if err != nil {
+			return nil, fmt.Errorf("converting %q to markdown: %s", e.Title, err)
+		}
+		e.Content = md
+		tmpl = mdT
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, e); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (h HugoRenderer) Ext() string {
+	if h.Format == formatMD {
+		return ".md"
+	}
+	return ".html"
+}
+
+func (h HugoRenderer) PageBundle() bool { return false }
+
+func (h HugoRenderer) CommentsBlock(comments []*Comment) string {
+	if h.Format == formatMD {
+		return commentsYAML(comments)
+	}
+	return commentsTOML(comments)
+}
+
+// zolaTempl follows Zola's TOML front matter conventions: tags live under
+// a [taxonomies] table rather than a top-level key, and draft is always
+// emitted rather than only when true.
+var zolaTempl = `+++
+title = {{ QuoteStringValue .Title }}
+date = {{ .Published }}
+updated = {{ .Updated }}
+draft = {{ if .Draft }}true{{ else }}false{{ end }}
+description = ""
+blogimport = true {{ with .Extra }}
+{{.}}{{ end }}
+{{ with .Tags.TomlString }}
+[taxonomies]
+tags = [{{ . }}]
+{{ end }}
+[author]
+	name = {{ QuoteStringValue .Author.Name }}
+	uri = {{ QuoteStringValue .Author.Uri }}
++++
+
+{{ .Content }}
+`
+
+var zolaT = template.Must(template.New("").Funcs(template.FuncMap{
+	"QuoteStringValue": QuoteStringValue,
+}).Parse(zolaTempl))
+
+// ZolaRenderer renders Zola's page-bundle front matter. Zola content is
+// always Markdown, regardless of -format, since index.md is the only
+// content file a Zola page bundle supports.
+type ZolaRenderer struct{}
+
+func (z ZolaRenderer) Render(e Entry) ([]byte, error) {
+	md, err := htmlToMarkdown(e.Content)
+	if err != nil {
+		return nil, fmt.Errorf("converting %q to markdown: %s", e.Title, err)
+	}
+	e.Content = md
+
+	var buf bytes.Buffer
+	if err := zolaT.Execute(&buf, e); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (z ZolaRenderer) Ext() string { return ".md" }
+
+func (z ZolaRenderer) PageBundle() bool { return true }
+
+func (z ZolaRenderer) CommentsBlock(comments []*Comment) string {
+	return commentsTOML(comments)
+}
+
+func newRenderer(opts writeOptions) (Renderer, error) {
+	if opts.templatePath != "" {
+		return loadCustomRenderer(opts.templatePath)
+	}
+
+	switch opts.target {
+	case targetHugo, "":
+		return HugoRenderer{Format: opts.format}, nil
+	case targetZola:
+		return ZolaRenderer{}, nil
+	default:
+		return nil, fmt.Errorf("unknown target %q", opts.target)
+	}
+}
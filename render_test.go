@@ -0,0 +1,51 @@
+package main
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func testEntry() Entry {
+	return Entry{
+		Title:     "Hello, World",
+		Published: Date(time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)),
+		Updated:   Date(time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)),
+		Content:   "<p>Hello <strong>there</strong>.</p>",
+		Tags: Tags{
+			{Name: "golang", Scheme: "http://www.blogger.com/atom/ns#"},
+		},
+		Author: Author{Name: "Nate Finch", Uri: "https://example.com"},
+	}
+}
+
+func TestRendererGoldenFiles(t *testing.T) {
+	tests := []struct {
+		name     string
+		renderer Renderer
+		golden   string
+	}{
+		{"hugo-html", HugoRenderer{Format: formatHTML}, "hugo.golden"},
+		{"hugo-md", HugoRenderer{Format: formatMD}, "hugo_md.golden"},
+		{"zola", ZolaRenderer{}, "zola.golden"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.renderer.Render(testEntry())
+			if err != nil {
+				t.Fatalf("Render: %s", err)
+			}
+
+			want, err := ioutil.ReadFile(filepath.Join("testdata", tt.golden))
+			if err != nil {
+				t.Fatalf("reading golden file: %s", err)
+			}
+
+			if string(got) != string(want) {
+				t.Errorf("Render() mismatch with %s\ngot:\n%s\nwant:\n%s", tt.golden, got, want)
+			}
+		})
+	}
+}
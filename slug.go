@@ -0,0 +1,90 @@
+package main
+
+import (
+	"net/url"
+	"path"
+	"strings"
+	"time"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// slugStrategy selects how writeEntry derives a post's filename from its
+// title (or permalink, when -filename-from-url is set).
+type slugStrategy string
+
+const (
+	// slugUnicode keeps any unicode.IsLetter rune, same as the tool has
+	// always done. It works, but non-Latin titles produce Cyrillic/CJK
+	// filenames that trip up some SSGs and web servers.
+	slugUnicode slugStrategy = "unicode"
+	// slugASCII transliterates to the closest ASCII representation
+	// before sanitizing, the way most SSG slug helpers do.
+	slugASCII slugStrategy = "ascii"
+	// slugDateTitle prefixes the sanitized title with the post's
+	// publish date, as YYYY/MM/DD/.
+	slugDateTitle slugStrategy = "date-title"
+)
+
+// makeSlug derives the path (relative to the target dir, without
+// extension) that entry e should be written to.
+func makeSlug(e Entry, strategy slugStrategy, filenameFromURL bool) string {
+	if filenameFromURL {
+		if slug := slugFromPermalink(e.Permalink()); slug != "" {
+			return slug
+		}
+	}
+
+	title := strings.ToLower(strings.Replace(strings.TrimSpace(e.Title), " ", "-", -1))
+
+	switch strategy {
+	case slugASCII:
+		return asciiSanitize(title)
+	case slugDateTitle:
+		return path.Join(time.Time(e.Published).Format("2006/01/02"), unicodeSanitize(title))
+	default:
+		return unicodeSanitize(title)
+	}
+}
+
+// slugFromPermalink derives a slug from a Blogger post's permalink, e.g.
+// "https://example.blogspot.com/2020/01/my-post-title.html" becomes
+// "2020/01/my-post-title", preserving the permalink's directory structure
+// so imported posts keep the original blog's URL for redirects.
+func slugFromPermalink(href string) string {
+	if href == "" {
+		return ""
+	}
+	u, err := url.Parse(href)
+	if err != nil {
+		return ""
+	}
+	trimmed := strings.Trim(u.Path, "/")
+	if trimmed == "" {
+		return ""
+	}
+	return strings.TrimSuffix(trimmed, path.Ext(trimmed))
+}
+
+// asciiSanitize transliterates s to its closest ASCII representation (by
+// Unicode-decomposing it and dropping combining marks) before applying the
+// same character whitelist as unicodeSanitize.
+func asciiSanitize(s string) string {
+	decomposed := norm.NFD.String(s)
+
+	target := make([]rune, 0, len(decomposed))
+	for _, r := range decomposed {
+		if unicode.Is(unicode.Mn, r) {
+			continue // combining mark stripped by the decomposition above
+		}
+		if r > unicode.MaxASCII {
+			continue
+		}
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '.' || r == '_' || r == '-' {
+			target = append(target, r)
+		}
+	}
+
+	return string(target)
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMakeSlug(t *testing.T) {
+	published := Date(time.Date(2020, 3, 4, 0, 0, 0, 0, time.UTC))
+
+	tests := []struct {
+		name     string
+		entry    Entry
+		strategy slugStrategy
+		fromURL  bool
+		want     string
+	}{
+		{
+			name:     "unicode keeps non-ascii letters",
+			entry:    Entry{Title: "Café Review"},
+			strategy: slugUnicode,
+			want:     "café-review",
+		},
+		{
+			name:     "ascii transliterates and strips combining marks",
+			entry:    Entry{Title: "Café Review"},
+			strategy: slugASCII,
+			want:     "cafe-review",
+		},
+		{
+			name:     "date-title prefixes the publish date",
+			entry:    Entry{Title: "Hello World", Published: published},
+			strategy: slugDateTitle,
+			want:     "2020/03/04/hello-world",
+		},
+		{
+			name: "filename-from-url overrides the title-derived slug",
+			entry: Entry{
+				Title: "Ignored Title",
+				Links: []Link{{Rel: "alternate", Href: "https://example.blogspot.com/2020/03/my-post-title.html"}},
+			},
+			strategy: slugUnicode,
+			fromURL:  true,
+			want:     "2020/03/my-post-title",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := makeSlug(tt.entry, tt.strategy, tt.fromURL)
+			if got != tt.want {
+				t.Errorf("makeSlug() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// templateFuncs are available to both the built-in templates and any
+// -template file a user supplies.
+var templateFuncs = template.FuncMap{
+	"QuoteStringValue": QuoteStringValue,
+	"slugify":          slugifyFunc,
+	"dateFormat":       dateFormatFunc,
+	"toYAML":           toYAMLFunc,
+	"toJSON":           toJSONFunc,
+}
+
+// slugifyFunc exposes the ascii slug strategy to user templates, so e.g. a
+// Jekyll template can build its own `YYYY-MM-DD-title.md` filename logic
+// without reimplementing sanitization.
+func slugifyFunc(s string) string {
+	return asciiSanitize(strings.ToLower(strings.Replace(strings.TrimSpace(s), " ", "-", -1)))
+}
+
+func dateFormatFunc(d Date, layout string) string {
+	return time.Time(d).Format(layout)
+}
+
+func toYAMLFunc(v interface{}) (string, error) {
+	b, err := yaml.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+func toJSONFunc(v interface{}) (string, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// CustomRenderer executes a user-supplied template, loaded with -template,
+// in place of the built-in Hugo/Zola renderers. It gets the same Entry
+// data and QuoteStringValue helper as the built-ins, plus slugify,
+// dateFormat, toYAML and toJSON.
+type CustomRenderer struct {
+	Tmpl *template.Template
+	ext  string
+}
+
+func (c CustomRenderer) Render(e Entry) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := c.Tmpl.Execute(&buf, e); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (c CustomRenderer) Ext() string { return c.ext }
+
+func (c CustomRenderer) PageBundle() bool { return false }
+
+// CommentsBlock defaults to TOML, since a custom template's front matter
+// dialect isn't known to us. Templates that want YAML or another format
+// should read .Extra themselves and reformat, or use -comments=sidecar
+// instead.
+func (c CustomRenderer) CommentsBlock(comments []*Comment) string {
+	return commentsTOML(comments)
+}
+
+// loadCustomRenderer parses the template at path for use as a Renderer.
+// The output extension is taken from the template's own filename (so
+// post.md.tmpl produces .md files), falling back to .html.
+func loadCustomRenderer(path string) (Renderer, error) {
+	name := filepath.Base(path)
+	tmpl, err := template.New(name).Funcs(templateFuncs).ParseFiles(path)
+	if err != nil {
+		return nil, fmt.Errorf("loading -template %q: %s", path, err)
+	}
+
+	ext := filepath.Ext(path)
+	if ext == ".tmpl" {
+		ext = filepath.Ext(strings.TrimSuffix(path, ext))
+	}
+	if ext == "" {
+		ext = ".html"
+	}
+
+	return CustomRenderer{Tmpl: tmpl, ext: ext}, nil
+}